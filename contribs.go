@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"time"
+
+	"sigs.k8s.io/yaml"
+)
+
+// manualContribution is one hand-curated entry in --contribs_file, for
+// work that isn't visible to any configured forge's activity API:
+// private repos, mailing-list patches, design docs, off-platform reviews.
+type manualContribution struct {
+	URLs        []string `json:"urls"`
+	Tags        []string `json:"tags"`
+	Desc        string   `json:"desc"`
+	SubmittedAt string   `json:"submitted-at"`
+	Status      string   `json:"status"`
+}
+
+// loadContribsFile parses --contribs_file with strict unmarshaling, so a
+// typo'd field name fails loudly instead of being silently dropped.
+func loadContribsFile(path string) ([]manualContribution, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read contribs file %q: %v", path, err)
+	}
+	var contribs []manualContribution
+	if err := yaml.UnmarshalStrict(b, &contribs); err != nil {
+		return nil, fmt.Errorf("unable to parse contribs file %q: %v", path, err)
+	}
+	return contribs, nil
+}
+
+// manualContributionsToContributions expands each manual entry into one
+// Contribution per URL, resolving status and submitted-at via whichever
+// configured forge's URL prefix matches when the file leaves them blank.
+func manualContributionsToContributions(contribs []manualContribution, forges []Forge) []Contribution {
+	var cs []Contribution
+	for _, c := range contribs {
+		for _, u := range c.URLs {
+			cs = append(cs, manualContributionToContribution(c, u, forges))
+		}
+	}
+	return cs
+}
+
+func manualContributionToContribution(c manualContribution, u string, forges []Forge) Contribution {
+	title := c.Desc
+	if title == "" {
+		title = u
+	}
+
+	state := StateUnknown
+	if c.Status != "" {
+		state = State(c.Status)
+	}
+
+	var submittedAt time.Time
+	if c.SubmittedAt != "" {
+		t, err := time.Parse(time.RFC3339, c.SubmittedAt)
+		if err != nil {
+			log.Printf("Unable to parse submitted-at %q for %q: %v", c.SubmittedAt, u, err)
+		} else {
+			submittedAt = t
+		}
+	}
+
+	if state == StateUnknown || submittedAt.IsZero() {
+		if f := forgeForURL(u, forges); f != nil {
+			if state == StateUnknown {
+				if s, err := f.FetchStatus(u); err != nil {
+					log.Printf("Unable to detect status for %q: %v", u, err)
+				} else {
+					state = s
+				}
+			}
+			if submittedAt.IsZero() {
+				if t, err := f.FetchSubmittedAt(u); err != nil {
+					log.Printf("Unable to detect submitted-at for %q: %v", u, err)
+				} else {
+					submittedAt = t
+				}
+			}
+		} else {
+			log.Printf("No configured forge matches %q; leave status/submitted-at unresolved", u)
+		}
+	}
+
+	return Contribution{URL: u, Title: title, State: state, SubmittedAt: submittedAt, Tags: c.Tags}
+}
+
+func forgeForURL(u string, forges []Forge) Forge {
+	for _, f := range forges {
+		if f.Matches(u) {
+			return f
+		}
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"log"
+	"sort"
+)
+
+type urlSet map[string]struct{}
+
+type eventSets struct {
+	contributions map[string]Contribution
+	merged        urlSet
+	abandoned     urlSet
+	underReview   urlSet
+	inProgress    urlSet
+	reviewed      urlSet
+	issues        urlSet
+}
+
+func newEventSets() *eventSets {
+	return &eventSets{
+		contributions: make(map[string]Contribution),
+		merged:        urlSet{},
+		abandoned:     urlSet{},
+		underReview:   urlSet{},
+		inProgress:    urlSet{},
+		reviewed:      urlSet{},
+		issues:        urlSet{},
+	}
+}
+
+// addContributions files each Contribution into its matching section,
+// overwriting any existing entry for that URL so the most recently
+// fetched data wins.
+func (e *eventSets) addContributions(cs []Contribution) {
+	for _, c := range cs {
+		e.contributions[c.URL] = c
+		e.removeFromSections(c.URL)
+		switch c.State {
+		case StateMerged:
+			e.merged[c.URL] = struct{}{}
+		case StateAbandoned:
+			e.abandoned[c.URL] = struct{}{}
+		case StateUnderReview:
+			e.underReview[c.URL] = struct{}{}
+		case StateInProgress:
+			e.inProgress[c.URL] = struct{}{}
+		case StateReviewed:
+			e.reviewed[c.URL] = struct{}{}
+		case StateIssue:
+			e.issues[c.URL] = struct{}{}
+		default:
+			log.Printf("Contribution %q has unknown state: %v", c.URL, c.State)
+		}
+	}
+}
+
+// removeFromSections deletes url from every section's urlSet, so
+// re-adding a Contribution under a new State doesn't leave it also
+// filed under its old one.
+func (e *eventSets) removeFromSections(url string) {
+	delete(e.merged, url)
+	delete(e.abandoned, url)
+	delete(e.underReview, url)
+	delete(e.inProgress, url)
+	delete(e.reviewed, url)
+	delete(e.issues, url)
+}
+
+// section is one named group of contributions, e.g. "Merged" or "Issues".
+type section struct {
+	title string
+	urls  urlSet
+}
+
+// sections lists every section in the order they should be reported, for
+// Renderers to walk without each hard-coding the set of statuses.
+func (e *eventSets) sections() []section {
+	return []section{
+		{"Merged", e.merged},
+		{"Abandoned", e.abandoned},
+		{"Under Review", e.underReview},
+		{"In Progress", e.inProgress},
+		{"Reviewed", e.reviewed},
+		{"Issues", e.issues},
+	}
+}
+
+// items resolves a section's URLs into reportItems, logging and skipping
+// any URL that's missing its contribution data. The result is sorted by
+// URL so that repeated runs over the same activity produce the same
+// output, instead of the random order map iteration would give.
+func (e *eventSets) items(s section) []reportItem {
+	its := make([]reportItem, 0, len(s.urls))
+	for url := range s.urls {
+		c, ok := e.contributions[url]
+		if !ok {
+			log.Printf("Did not have contribution data for: %q", url)
+			continue
+		}
+		its = append(its, reportItem{URL: c.URL, Title: c.Title, Repo: c.Repo, Number: c.Number, SubmittedAt: c.SubmittedAt, Tags: c.Tags})
+	}
+	sort.Slice(its, func(i, j int) bool { return its[i].URL < its[j].URL })
+	return its
+}
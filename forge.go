@@ -0,0 +1,61 @@
+package main
+
+import "time"
+
+// State is the lifecycle state of a Contribution, as understood by the
+// forge it came from.
+type State string
+
+const (
+	StateMerged      State = "merged"
+	StateAbandoned   State = "abandoned"
+	StateUnderReview State = "under-review"
+	StateInProgress  State = "in-progress"
+	StateReviewed    State = "reviewed"
+	StateIssue       State = "issue"
+	StateUnknown     State = "unknown"
+)
+
+// Contribution is a single piece of activity discovered on a forge: a PR,
+// change, merge request, or issue that the user opened or reviewed.
+type Contribution struct {
+	URL   string
+	Title string
+	State State
+	// Repo is a forge-specific "owner/repo"-style grouping key (a Gerrit
+	// project, a GitLab/Forgejo namespace+project, a GitHub owner/repo),
+	// and Number is the contribution's number within it.
+	Repo   string
+	Number int
+	// SubmittedAt is when the contribution was first opened. Only
+	// manually curated entries carry it; contributions returned by
+	// FetchActivity leave it as the zero Time. It's also zero for a
+	// manual entry with no submitted-at that no forge could resolve.
+	SubmittedAt time.Time
+	// Tags are free-form labels from a manually curated entry, e.g.
+	// "design-doc" or "mailing-list". Forge-discovered contributions
+	// never set this.
+	Tags []string
+}
+
+// Forge is a code review/hosting system that a user's activity can be
+// pulled from. GitHub, Gerrit, GitLab, and Forgejo all implement it, and
+// main merges their output into a single report.
+type Forge interface {
+	// FetchActivity returns the Contributions for user between start and
+	// end. The GitHub implementation includes PRs and issues the user
+	// reviewed or commented on; the Gerrit, GitLab, and Forgejo ones
+	// return only what the user authored.
+	FetchActivity(user string, start, end time.Time) ([]Contribution, error)
+	// FetchStatus looks up the current State of a single contribution by
+	// its URL. It's used for contributions discovered outside of
+	// FetchActivity, e.g. from a hand-curated contributions file.
+	FetchStatus(url string) (State, error)
+	// FetchSubmittedAt looks up when a contribution was first submitted,
+	// for the same out-of-band case as FetchStatus.
+	FetchSubmittedAt(url string) (time.Time, error)
+	// Matches reports whether url points at this forge, so a manual
+	// contribution missing status/submitted-at can be routed to the
+	// right one.
+	Matches(url string) bool
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// forgejoIssue is the subset of a Forgejo/Gitea Issue we care about; pull
+// requests are represented as issues with a non-nil PullRequest field.
+// https://codeberg.org/api/swagger
+type forgejoIssue struct {
+	Number      int        `json:"number"`
+	HTMLURL     string     `json:"html_url"`
+	Title       string     `json:"title"`
+	State       string     `json:"state"` // open, closed
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	PullRequest *struct {
+		Merged bool `json:"merged"`
+		Draft  bool `json:"draft"`
+	} `json:"pull_request"`
+	User struct {
+		UserName string `json:"login"`
+	} `json:"user"`
+}
+
+// forgejoForge implements Forge against a Forgejo (or Gitea) instance's
+// v1 REST API.
+type forgejoForge struct {
+	baseURL string
+	client  *http.Client
+}
+
+func newForgejoForge(baseURL string) *forgejoForge {
+	return &forgejoForge{baseURL: strings.TrimSuffix(baseURL, "/"), client: http.DefaultClient}
+}
+
+func (f *forgejoForge) FetchActivity(user string, start, end time.Time) ([]Contribution, error) {
+	issues, err := f.searchIssues(url.Values{
+		"type":       {"pulls"},
+		"state":      {"all"},
+		"created_by": {user},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	cs := make([]Contribution, 0, len(issues))
+	for _, i := range issues {
+		if i.UpdatedAt.Before(start) || i.UpdatedAt.After(end) {
+			continue
+		}
+		cs = append(cs, Contribution{
+			URL:    i.HTMLURL,
+			Title:  i.Title,
+			State:  forgejoState(i, user),
+			Repo:   forgejoRepoOf(i.HTMLURL),
+			Number: i.Number,
+		})
+	}
+	return cs, nil
+}
+
+func (f *forgejoForge) FetchStatus(issueURL string) (State, error) {
+	owner, repo, number, err := parseForgejoIssueURL(issueURL)
+	if err != nil {
+		return StateUnknown, err
+	}
+	i, err := f.getIssue(owner, repo, number)
+	if err != nil {
+		return StateUnknown, err
+	}
+	return forgejoState(i, *user), nil
+}
+
+func (f *forgejoForge) FetchSubmittedAt(issueURL string) (time.Time, error) {
+	owner, repo, number, err := parseForgejoIssueURL(issueURL)
+	if err != nil {
+		return time.Time{}, err
+	}
+	i, err := f.getIssue(owner, repo, number)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return i.CreatedAt, nil
+}
+
+// parseForgejoIssueURL cracks an issue/PR HTML URL, e.g.
+// ".../owner/repo/pulls/5", into the owner/repo/number that the
+// repo-scoped issue endpoint needs. Issue numbers are only unique within
+// a repo, so this can't be resolved with an instance-wide search.
+func parseForgejoIssueURL(issueURL string) (owner, repo string, number int, err error) {
+	u, err := url.Parse(issueURL)
+	if err != nil {
+		return "", "", 0, fmt.Errorf("unable to parse issue URL %q: %v", issueURL, err)
+	}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) < 4 {
+		return "", "", 0, fmt.Errorf("unexpected issue URL shape: %q", issueURL)
+	}
+	number, err = strconv.Atoi(parts[3])
+	if err != nil {
+		return "", "", 0, fmt.Errorf("unable to parse issue number from %q: %v", issueURL, err)
+	}
+	return parts[0], parts[1], number, nil
+}
+
+func (f *forgejoForge) Matches(url string) bool {
+	return strings.HasPrefix(url, f.baseURL)
+}
+
+// forgejoRepoOf takes the host plus first two path segments of an
+// issue/PR's HTML URL (host/owner/repo), which is safe here since Forgejo
+// repo names, unlike Gerrit projects, never contain a literal slash.
+func forgejoRepoOf(htmlURL string) string {
+	u, err := url.Parse(htmlURL)
+	if err != nil {
+		return htmlURL
+	}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) < 2 {
+		return u.Host
+	}
+	return fmt.Sprintf("%s/%s/%s", u.Host, parts[0], parts[1])
+}
+
+// getIssue fetches a single issue/PR by its repo-scoped number, the only
+// way to look one up unambiguously since numbers repeat across repos.
+func (f *forgejoForge) getIssue(owner, repo string, number int) (forgejoIssue, error) {
+	u := fmt.Sprintf("%s/api/v1/repos/%s/%s/issues/%d", f.baseURL, owner, repo, number)
+	resp, err := f.client.Get(u)
+	if err != nil {
+		return forgejoIssue{}, fmt.Errorf("unable to get forgejo issue: %v", err)
+	}
+	defer resp.Body.Close()
+
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return forgejoIssue{}, fmt.Errorf("unable to read forgejo response: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return forgejoIssue{}, fmt.Errorf("forgejo get issue %s/%s#%d failed: %s: %s", owner, repo, number, resp.Status, b)
+	}
+
+	var i forgejoIssue
+	if err := json.Unmarshal(b, &i); err != nil {
+		return forgejoIssue{}, fmt.Errorf("unable to decode forgejo response: %v", err)
+	}
+	return i, nil
+}
+
+func (f *forgejoForge) searchIssues(q url.Values) ([]forgejoIssue, error) {
+	u := fmt.Sprintf("%s/api/v1/repos/issues/search?%s", f.baseURL, q.Encode())
+	resp, err := f.client.Get(u)
+	if err != nil {
+		return nil, fmt.Errorf("unable to query forgejo issues: %v", err)
+	}
+	defer resp.Body.Close()
+
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read forgejo response: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("forgejo query %q failed: %s: %s", q.Encode(), resp.Status, b)
+	}
+
+	var issues []forgejoIssue
+	if err := json.Unmarshal(b, &issues); err != nil {
+		return nil, fmt.Errorf("unable to decode forgejo response: %v", err)
+	}
+	return issues, nil
+}
+
+func forgejoState(i forgejoIssue, user string) State {
+	if i.User.UserName != user {
+		return StateReviewed
+	}
+	if i.PullRequest == nil {
+		return StateIssue
+	}
+	switch i.State {
+	case "open":
+		if i.PullRequest.Draft {
+			return StateInProgress
+		}
+		return StateUnderReview
+	case "closed":
+		if i.PullRequest.Merged {
+			return StateMerged
+		}
+		return StateAbandoned
+	default:
+		return StateUnknown
+	}
+}
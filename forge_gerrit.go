@@ -0,0 +1,174 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// gerritTimeFormat is the layout Gerrit's REST API uses for timestamps,
+// e.g. "2020-01-02 15:04:05.000000000".
+const gerritTimeFormat = "2006-01-02 15:04:05.000000000"
+
+// gerritTime wraps time.Time to decode Gerrit's non-standard timestamp
+// format from JSON.
+type gerritTime struct {
+	time.Time
+}
+
+func (t *gerritTime) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	parsed, err := time.Parse(gerritTimeFormat, s)
+	if err != nil {
+		return err
+	}
+	t.Time = parsed
+	return nil
+}
+
+// gerritChange is the subset of a Gerrit ChangeInfo that we care about.
+// https://gerrit-review.googlesource.com/Documentation/rest-api-changes.html#change-info
+type gerritChange struct {
+	ChangeID string     `json:"change_id"`
+	Project  string     `json:"project"`
+	Number   int        `json:"_number"`
+	Subject  string     `json:"subject"`
+	Status   string     `json:"status"`
+	Created  gerritTime `json:"created"`
+	Updated  gerritTime `json:"updated"`
+	Owner    struct {
+		Username string `json:"username"`
+	} `json:"owner"`
+}
+
+// gerritForge implements Forge against a Gerrit instance's REST API.
+type gerritForge struct {
+	baseURL string
+	client  *http.Client
+}
+
+func newGerritForge(baseURL string) *gerritForge {
+	return &gerritForge{baseURL: strings.TrimSuffix(baseURL, "/"), client: http.DefaultClient}
+}
+
+func (g *gerritForge) FetchActivity(user string, start, end time.Time) ([]Contribution, error) {
+	q := fmt.Sprintf("owner:%s+after:%s", user, start.Format("2006-01-02"))
+	changes, err := g.queryChanges(q)
+	if err != nil {
+		return nil, err
+	}
+
+	cs := make([]Contribution, 0, len(changes))
+	for _, c := range changes {
+		if c.Updated.Before(start) || c.Updated.After(end) {
+			continue
+		}
+		cs = append(cs, Contribution{
+			URL:    g.changeURL(c),
+			Title:  c.Subject,
+			State:  gerritState(c, user),
+			Repo:   c.Project,
+			Number: c.Number,
+		})
+	}
+	return cs, nil
+}
+
+func (g *gerritForge) FetchStatus(changeURL string) (State, error) {
+	number, err := lastPathSegmentInt(changeURL)
+	if err != nil {
+		return StateUnknown, err
+	}
+	changes, err := g.queryChanges(fmt.Sprintf("change:%d", number))
+	if err != nil {
+		return StateUnknown, err
+	}
+	if len(changes) == 0 {
+		return StateUnknown, fmt.Errorf("no such change: %q", changeURL)
+	}
+	return gerritState(changes[0], *user), nil
+}
+
+func (g *gerritForge) FetchSubmittedAt(changeURL string) (time.Time, error) {
+	number, err := lastPathSegmentInt(changeURL)
+	if err != nil {
+		return time.Time{}, err
+	}
+	changes, err := g.queryChanges(fmt.Sprintf("change:%d", number))
+	if err != nil {
+		return time.Time{}, err
+	}
+	if len(changes) == 0 {
+		return time.Time{}, fmt.Errorf("no such change: %q", changeURL)
+	}
+	return changes[0].Created.Time, nil
+}
+
+func (g *gerritForge) Matches(url string) bool {
+	return strings.HasPrefix(url, g.baseURL)
+}
+
+// queryChanges issues a Gerrit changes query and decodes the response,
+// stripping Gerrit's anti-XSSI `)]}'` prefix line first.
+func (g *gerritForge) queryChanges(query string) ([]gerritChange, error) {
+	u := fmt.Sprintf("%s/changes/?q=%s&o=DETAILED_LABELS", g.baseURL, url.QueryEscape(query))
+	resp, err := g.client.Get(u)
+	if err != nil {
+		return nil, fmt.Errorf("unable to query gerrit changes: %v", err)
+	}
+	defer resp.Body.Close()
+
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read gerrit response: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("gerrit query %q failed: %s: %s", query, resp.Status, b)
+	}
+
+	b = stripGerritXSSIPrefix(b)
+	var changes []gerritChange
+	if err := json.Unmarshal(b, &changes); err != nil {
+		return nil, fmt.Errorf("unable to decode gerrit response: %v", err)
+	}
+	return changes, nil
+}
+
+// stripGerritXSSIPrefix removes the leading `)]}'` line Gerrit prepends to
+// every JSON response to prevent it from being interpreted as executable
+// JavaScript when loaded directly.
+func stripGerritXSSIPrefix(b []byte) []byte {
+	if i := strings.IndexByte(string(b), '\n'); i != -1 && strings.HasPrefix(string(b), ")]}'") {
+		return b[i+1:]
+	}
+	return b
+}
+
+func (g *gerritForge) changeURL(c gerritChange) string {
+	return fmt.Sprintf("%s/c/%s/+/%d", g.baseURL, c.Project, c.Number)
+}
+
+func gerritState(c gerritChange, user string) State {
+	if c.Owner.Username != user {
+		return StateReviewed
+	}
+	switch c.Status {
+	case "NEW":
+		return StateUnderReview
+	case "MERGED":
+		return StateMerged
+	case "ABANDONED":
+		return StateAbandoned
+	default:
+		log.Printf("Change is in an unknown state: %+v", c)
+		return StateUnknown
+	}
+}
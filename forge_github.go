@@ -0,0 +1,304 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/github"
+	"golang.org/x/oauth2"
+)
+
+// githubForge implements Forge against the github.com REST v3 API. URLs
+// outside https://github.com/ are not handled.
+type githubForge struct {
+	client     *github.Client
+	httpClient *http.Client
+}
+
+func newGitHubForge() *githubForge {
+	hc := oauthClient()
+	return &githubForge{client: github.NewClient(hc), httpClient: hc}
+}
+
+func oauthClient() *http.Client {
+	oauthToken := readOauthToken()
+	ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: oauthToken})
+	client := oauth2.NewClient(context.Background(), ts)
+	if *cacheDir != "" {
+		client = wrapWithDiskCache(client, *cacheDir)
+	}
+	return client
+}
+
+func readOauthToken() string {
+	b, err := ioutil.ReadFile(*tokenFile)
+	if err != nil {
+		log.Fatalf("Unable to read tokenFile, '%s': %v", *tokenFile, err)
+	}
+	s := string(b)
+	return strings.TrimSuffix(s, "\n")
+}
+
+func (g *githubForge) FetchActivity(user string, start, end time.Time) ([]Contribution, error) {
+	events, err := g.listEvents(user)
+	if err != nil {
+		return nil, err
+	}
+	fe := filterEventsForTime(events, start, end)
+	ge := organizeEvents(fe)
+	return ge.contributions(g, user)
+}
+
+func (g *githubForge) FetchStatus(url string) (State, error) {
+	info := crackPRInfo(url)
+	pr, _, err := g.client.PullRequests.Get(context.TODO(), info.owner, info.repo, info.number)
+	if err != nil {
+		return StateUnknown, err
+	}
+	return prState(pr, *user), nil
+}
+
+func (g *githubForge) FetchSubmittedAt(url string) (time.Time, error) {
+	info := crackPRInfo(url)
+	pr, _, err := g.client.PullRequests.Get(context.TODO(), info.owner, info.repo, info.number)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return pr.GetCreatedAt(), nil
+}
+
+func (g *githubForge) Matches(url string) bool {
+	return strings.HasPrefix(url, "https://github.com/")
+}
+
+func (g *githubForge) listEvents(user string) ([]*github.Event, error) {
+	events := make([]*github.Event, 0)
+	page := 1
+	for {
+		e, r, err := g.client.Activity.ListEventsPerformedByUser(context.TODO(), user, true, &github.ListOptions{
+			Page: page,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("unable to list events for page %v: %v", page, err)
+		}
+		events = append(events, e...)
+		page = r.NextPage
+		if page == 0 {
+			return events, nil
+		}
+	}
+}
+
+func filterEventsForTime(unfiltered []*github.Event, start, end time.Time) []*github.Event {
+	events := make([]*github.Event, 0)
+	for _, e := range unfiltered {
+		if e.CreatedAt.After(start) && e.CreatedAt.Before(end) {
+			events = append(events, e)
+		}
+	}
+	return events
+}
+
+type issuesAndPRs struct {
+	names  map[string]string
+	issues map[string]*github.Issue
+	prs    map[string]prInfo
+}
+
+type prInfo struct {
+	owner  string
+	repo   string
+	number int
+}
+
+func organizeEvents(events []*github.Event) *issuesAndPRs {
+	parsed := make([]interface{}, 0, len(events))
+	for _, event := range events {
+		p, err := event.ParsePayload()
+		if err != nil {
+			log.Fatalf("Unable to parse event: %v, %v", err, event)
+		}
+		parsed = append(parsed, p)
+	}
+	ge := &issuesAndPRs{
+		names:  make(map[string]string),
+		issues: make(map[string]*github.Issue),
+		prs:    make(map[string]prInfo),
+	}
+	for _, event := range parsed {
+		switch e := event.(type) {
+		case *github.IssueCommentEvent:
+			ge.addIssue(e.Issue)
+		case *github.IssuesEvent:
+			ge.addIssue(e.Issue)
+		case *github.PullRequestEvent:
+			ge.addPR(e.PullRequest)
+		case *github.PullRequestReviewCommentEvent:
+			ge.addPR(e.PullRequest)
+		// Everything below this line is ignored for now.
+		case *github.CommitCommentEvent:
+			log.Printf("Hit a commitCommentEvent")
+		case *github.CreateEvent:
+		case *github.PushEvent:
+		case *github.DeleteEvent:
+		default:
+			log.Printf("Hit some other event type: %T", event)
+		}
+	}
+	return ge
+}
+
+type nameable interface {
+	GetTitle() string
+	GetHTMLURL() string
+}
+
+func (e *issuesAndPRs) addName(n nameable) string {
+	url := n.GetHTMLURL()
+	// Since we iterate in reverse chronological order, the first entry, should be the most
+	// up-to-date.
+	if _, ok := e.names[url]; !ok {
+		e.overrideName(url, n)
+	}
+	return url
+}
+
+func (e *issuesAndPRs) overrideName(url string, n nameable) {
+	e.names[url] = n.GetTitle()
+}
+
+func (e *issuesAndPRs) addIssue(i *github.Issue) {
+	url := e.addName(i)
+	if i.IsPullRequest() {
+		e.prs[url] = crackPRInfo(i.GetHTMLURL())
+	} else if _, ok := e.issues[url]; !ok {
+		e.issues[url] = i
+	}
+}
+
+func (e *issuesAndPRs) addPR(pr *github.PullRequest) {
+	url := e.addName(pr)
+	if _, ok := e.prs[url]; !ok {
+		e.prs[url] = crackPRInfo(pr.GetHTMLURL())
+	}
+}
+
+// contributions resolves the latest state of every PR and issue gathered
+// from events into a flat list of Contributions.
+func (e *issuesAndPRs) contributions(g *githubForge, user string) ([]Contribution, error) {
+	cs := make([]Contribution, 0, len(e.prs)+len(e.issues))
+
+	prs, err := e.getPRs(g)
+	if err != nil {
+		return nil, err
+	}
+	for url, pr := range prs {
+		info := e.prs[url]
+		cs = append(cs, Contribution{
+			URL:    url,
+			Title:  e.names[url],
+			State:  prState(pr, user),
+			Repo:   fmt.Sprintf("%s/%s", info.owner, info.repo),
+			Number: info.number,
+		})
+	}
+
+	for url := range e.issues {
+		info := crackPRInfo(url)
+		cs = append(cs, Contribution{
+			URL:    url,
+			Title:  e.names[url],
+			State:  StateIssue,
+			Repo:   fmt.Sprintf("%s/%s", info.owner, info.repo),
+			Number: info.number,
+		})
+	}
+	return cs, nil
+}
+
+// getPRs resolves every PR gathered from events to its latest state, via
+// the GraphQL API if --api=graphql, falling back to one REST call per PR
+// on error.
+func (e *issuesAndPRs) getPRs(g *githubForge) (map[string]*github.PullRequest, error) {
+	if *api == "graphql" {
+		newPRs, err := g.getPRsGraphQL(e.prs)
+		if err != nil {
+			log.Printf("GraphQL PR fetch failed, falling back to REST: %v", err)
+		} else {
+			for url, newPR := range newPRs {
+				e.overrideName(url, newPR)
+			}
+			return newPRs, nil
+		}
+	}
+
+	newPRs := make(map[string]*github.PullRequest)
+	for url, pr := range e.prs {
+		newPR, _, err := g.client.PullRequests.Get(context.TODO(), pr.owner, pr.repo, pr.number)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get PR: %q, %v", url, err)
+		}
+		newPRs[url] = newPR
+		e.overrideName(url, newPR)
+	}
+	return newPRs, nil
+}
+
+// prState maps a PR's GitHub state onto our forge-agnostic State, relative
+// to user.
+func prState(pr *github.PullRequest, user string) State {
+	if pr.GetUser().GetLogin() != user {
+		return StateReviewed
+	}
+	switch pr.GetState() {
+	case "open":
+		if isWorkInProgress(pr.Labels) {
+			return StateInProgress
+		}
+		return StateUnderReview
+	case "closed":
+		if pr.GetMerged() {
+			return StateMerged
+		}
+		return StateAbandoned
+	default:
+		log.Printf("PR is in an unknown state: %+v", pr)
+		return StateUnknown
+	}
+}
+
+func isWorkInProgress(labels []*github.Label) bool {
+	for _, l := range labels {
+		if l.GetName() == "do-not-merge/work-in-progress" {
+			return true
+		}
+	}
+	return false
+}
+
+func crackPRInfo(url string) prInfo {
+	prefix := "https://github.com/"
+	if !strings.HasPrefix(url, prefix) {
+		log.Fatalf("Bad prefix: %q", url)
+	}
+	url = strings.TrimPrefix(url, prefix)
+	splits := strings.Split(url, "/")
+	if len(splits) < 4 {
+		log.Fatalf("Incorrect number of splits: %q", url)
+	}
+	n, err := strconv.Atoi(splits[3])
+	if err != nil {
+		log.Fatalf("Unable to parse the fourth split: %q", url)
+	}
+	return prInfo{
+		owner:  splits[0],
+		repo:   splits[1],
+		number: n,
+	}
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// gitlabMergeRequest is the subset of a GitLab MergeRequest we care about.
+// https://docs.gitlab.com/ee/api/merge_requests.html
+type gitlabMergeRequest struct {
+	IID       int       `json:"iid"`
+	WebURL    string    `json:"web_url"`
+	Title     string    `json:"title"`
+	State     string    `json:"state"` // opened, closed, merged
+	Draft     bool      `json:"draft"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Author    struct {
+		Username string `json:"username"`
+	} `json:"author"`
+}
+
+// gitlabForge implements Forge against a GitLab instance's v4 REST API.
+type gitlabForge struct {
+	baseURL string
+	client  *http.Client
+}
+
+func newGitLabForge(baseURL string) *gitlabForge {
+	return &gitlabForge{baseURL: strings.TrimSuffix(baseURL, "/"), client: http.DefaultClient}
+}
+
+func (g *gitlabForge) FetchActivity(user string, start, end time.Time) ([]Contribution, error) {
+	mrs, err := g.searchMergeRequests(url.Values{
+		"author_username": {user},
+		"scope":           {"all"},
+		"updated_after":   {start.Format(time.RFC3339)},
+		"updated_before":  {end.Format(time.RFC3339)},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	cs := make([]Contribution, 0, len(mrs))
+	for _, mr := range mrs {
+		cs = append(cs, Contribution{
+			URL:    mr.WebURL,
+			Title:  mr.Title,
+			State:  gitlabState(mr, user),
+			Repo:   gitlabRepoOf(mr.WebURL),
+			Number: mr.IID,
+		})
+	}
+	return cs, nil
+}
+
+func (g *gitlabForge) FetchStatus(mrURL string) (State, error) {
+	projectPath, iid, err := parseGitLabMRURL(mrURL)
+	if err != nil {
+		return StateUnknown, err
+	}
+	mr, err := g.getMergeRequest(projectPath, iid)
+	if err != nil {
+		return StateUnknown, err
+	}
+	return gitlabState(mr, *user), nil
+}
+
+func (g *gitlabForge) FetchSubmittedAt(mrURL string) (time.Time, error) {
+	projectPath, iid, err := parseGitLabMRURL(mrURL)
+	if err != nil {
+		return time.Time{}, err
+	}
+	mr, err := g.getMergeRequest(projectPath, iid)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return mr.CreatedAt, nil
+}
+
+// parseGitLabMRURL cracks a merge request's web URL, e.g.
+// ".../namespace/project/-/merge_requests/5", into the project path and
+// iid the project-scoped merge request endpoint needs. An iid is only
+// unique within a project, so this can't be resolved with an
+// instance-wide search.
+func parseGitLabMRURL(mrURL string) (projectPath string, iid int, err error) {
+	const sep = "/-/merge_requests/"
+	i := strings.Index(mrURL, sep)
+	if i == -1 {
+		return "", 0, fmt.Errorf("unexpected merge request URL shape: %q", mrURL)
+	}
+	u, err := url.Parse(mrURL[:i])
+	if err != nil {
+		return "", 0, fmt.Errorf("unable to parse merge request URL %q: %v", mrURL, err)
+	}
+	iid, err = lastPathSegmentInt(mrURL)
+	if err != nil {
+		return "", 0, err
+	}
+	return strings.Trim(u.Path, "/"), iid, nil
+}
+
+func (g *gitlabForge) Matches(url string) bool {
+	return strings.HasPrefix(url, g.baseURL)
+}
+
+// gitlabRepoOf strips the "/-/merge_requests/<iid>" suffix off a merge
+// request's web URL, leaving the host plus "namespace/project" path that
+// GitLab groups projects under. Namespaces may themselves contain
+// slashes, so this can't just take the first two path segments the way a
+// GitHub owner/repo can.
+func gitlabRepoOf(webURL string) string {
+	const sep = "/-/merge_requests/"
+	if i := strings.Index(webURL, sep); i != -1 {
+		webURL = webURL[:i]
+	}
+	u, err := url.Parse(webURL)
+	if err != nil {
+		return webURL
+	}
+	return u.Host + u.Path
+}
+
+// getMergeRequest fetches a single merge request by its project-scoped
+// iid, the only way to look one up unambiguously since iids repeat
+// across projects.
+func (g *gitlabForge) getMergeRequest(projectPath string, iid int) (gitlabMergeRequest, error) {
+	u := fmt.Sprintf("%s/api/v4/projects/%s/merge_requests/%d", g.baseURL, url.PathEscape(projectPath), iid)
+	resp, err := g.client.Get(u)
+	if err != nil {
+		return gitlabMergeRequest{}, fmt.Errorf("unable to get gitlab merge request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return gitlabMergeRequest{}, fmt.Errorf("unable to read gitlab response: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return gitlabMergeRequest{}, fmt.Errorf("gitlab get merge request %s!%d failed: %s: %s", projectPath, iid, resp.Status, b)
+	}
+
+	var mr gitlabMergeRequest
+	if err := json.Unmarshal(b, &mr); err != nil {
+		return gitlabMergeRequest{}, fmt.Errorf("unable to decode gitlab response: %v", err)
+	}
+	return mr, nil
+}
+
+func (g *gitlabForge) searchMergeRequests(q url.Values) ([]gitlabMergeRequest, error) {
+	u := fmt.Sprintf("%s/api/v4/merge_requests?%s", g.baseURL, q.Encode())
+	resp, err := g.client.Get(u)
+	if err != nil {
+		return nil, fmt.Errorf("unable to query gitlab merge requests: %v", err)
+	}
+	defer resp.Body.Close()
+
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read gitlab response: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("gitlab query %q failed: %s: %s", q.Encode(), resp.Status, b)
+	}
+
+	var mrs []gitlabMergeRequest
+	if err := json.Unmarshal(b, &mrs); err != nil {
+		return nil, fmt.Errorf("unable to decode gitlab response: %v", err)
+	}
+	return mrs, nil
+}
+
+func gitlabState(mr gitlabMergeRequest, user string) State {
+	if mr.Author.Username != user {
+		return StateReviewed
+	}
+	switch mr.State {
+	case "opened":
+		if mr.Draft {
+			return StateInProgress
+		}
+		return StateUnderReview
+	case "merged":
+		return StateMerged
+	case "closed":
+		return StateAbandoned
+	default:
+		return StateUnknown
+	}
+}
@@ -0,0 +1,18 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// lastPathSegmentInt parses the final /-separated segment of a URL as an
+// integer, e.g. the merge request IID or issue number in a web URL.
+func lastPathSegmentInt(u string) (int, error) {
+	parts := strings.Split(strings.TrimRight(u, "/"), "/")
+	n, err := strconv.Atoi(parts[len(parts)-1])
+	if err != nil {
+		return 0, fmt.Errorf("unable to parse number from %q: %v", u, err)
+	}
+	return n, nil
+}
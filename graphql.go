@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-github/github"
+)
+
+// graphqlBatchSize caps how many PRs are aliased into a single GraphQL
+// query, to stay under GitHub's per-request node-cost limit (~500 nodes /
+// 5000 points). Each alias requests a repository, a pullRequest, and up
+// to 20 labels, so 20 PRs come to at most 440 nodes.
+const graphqlBatchSize = 20
+
+// graphqlPRResult is the subset of a GraphQL PullRequest we request.
+type graphqlPRResult struct {
+	State  string `json:"state"` // OPEN, CLOSED, or MERGED
+	Merged bool   `json:"merged"`
+	Title  string `json:"title"`
+	URL    string `json:"url"`
+	Author struct {
+		Login string `json:"login"`
+	} `json:"author"`
+	Labels struct {
+		Nodes []struct {
+			Name string `json:"name"`
+		} `json:"nodes"`
+	} `json:"labels"`
+}
+
+type graphqlResponse struct {
+	Data map[string]struct {
+		PullRequest *graphqlPRResult `json:"pullRequest"`
+	} `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// getPRsGraphQL resolves every PR in prs with the GitHub v4 GraphQL API
+// instead of one REST call per PR, collapsing what can be dozens of
+// round-trips into one or two.
+func (g *githubForge) getPRsGraphQL(prs map[string]prInfo) (map[string]*github.PullRequest, error) {
+	urls := make([]string, 0, len(prs))
+	for url := range prs {
+		urls = append(urls, url)
+	}
+
+	results := make(map[string]*github.PullRequest, len(urls))
+	for _, chunk := range chunkStrings(urls, graphqlBatchSize) {
+		res, err := g.queryPRsGraphQL(prs, chunk)
+		if err != nil {
+			return nil, err
+		}
+		for url, r := range res {
+			results[url] = graphqlResultToPR(r)
+		}
+	}
+	return results, nil
+}
+
+// queryPRsGraphQL issues one GraphQL query aliasing each PR in urls as
+// repository(owner:..., name:...) { pullRequest(number:...) {...} }.
+func (g *githubForge) queryPRsGraphQL(prs map[string]prInfo, urls []string) (map[string]graphqlPRResult, error) {
+	var fields strings.Builder
+	aliases := make(map[string]string, len(urls))
+	for i, url := range urls {
+		info := prs[url]
+		alias := fmt.Sprintf("pr%d", i)
+		aliases[alias] = url
+		fmt.Fprintf(&fields, "%s: repository(owner: %q, name: %q) { pullRequest(number: %d) { state merged title url author { login } labels(first: 20) { nodes { name } } } }\n",
+			alias, info.owner, info.repo, info.number)
+	}
+	query := fmt.Sprintf("query {\n%s}", fields.String())
+
+	body, err := json.Marshal(map[string]string{"query": query})
+	if err != nil {
+		return nil, fmt.Errorf("unable to build graphql query: %v", err)
+	}
+	req, err := http.NewRequest(http.MethodPost, "https://api.github.com/graphql", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("unable to build graphql request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := g.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("unable to issue graphql request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var gr graphqlResponse
+	if err := json.NewDecoder(resp.Body).Decode(&gr); err != nil {
+		return nil, fmt.Errorf("unable to decode graphql response: %v", err)
+	}
+	if len(gr.Errors) > 0 {
+		return nil, fmt.Errorf("graphql query returned errors: %v", gr.Errors)
+	}
+
+	results := make(map[string]graphqlPRResult, len(urls))
+	for alias, url := range aliases {
+		repo, ok := gr.Data[alias]
+		if !ok || repo.PullRequest == nil {
+			return nil, fmt.Errorf("graphql response missing result for %q", url)
+		}
+		results[url] = *repo.PullRequest
+	}
+	return results, nil
+}
+
+// graphqlResultToPR adapts a graphqlPRResult into a *github.PullRequest so
+// it can flow through the same prState logic as the REST path.
+func graphqlResultToPR(r graphqlPRResult) *github.PullRequest {
+	state := strings.ToLower(r.State)
+	if state == "merged" {
+		state = "closed"
+	}
+	pr := &github.PullRequest{
+		Title:   github.String(r.Title),
+		HTMLURL: github.String(r.URL),
+		State:   github.String(state),
+		Merged:  github.Bool(r.Merged),
+		User:    &github.User{Login: github.String(r.Author.Login)},
+	}
+	for _, l := range r.Labels.Nodes {
+		pr.Labels = append(pr.Labels, &github.Label{Name: github.String(l.Name)})
+	}
+	return pr
+}
+
+// chunkStrings splits ss into slices of at most size elements.
+func chunkStrings(ss []string, size int) [][]string {
+	var chunks [][]string
+	for size < len(ss) {
+		ss, chunks = ss[size:], append(chunks, ss[:size:size])
+	}
+	return append(chunks, ss)
+}
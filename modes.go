@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/github"
+)
+
+// runRangeStats aggregates counts of merged/reviewed/opened activity over
+// an arbitrary --from/--to window, across all configured forges.
+func runRangeStats() {
+	fromTime, err := time.Parse(timeFormat, *from)
+	if err != nil {
+		log.Fatalf("Unable to parse --from time '%s': %v", *from, err)
+	}
+	toTime, err := time.Parse(timeFormat, *to)
+	if err != nil {
+		log.Fatalf("Unable to parse --to time '%s': %v", *to, err)
+	}
+
+	counts := make(map[State]int)
+	for _, f := range configuredForges() {
+		cs, err := f.FetchActivity(*user, fromTime, toTime)
+		if err != nil {
+			log.Fatalf("Unable to fetch activity: %v", err)
+		}
+		for _, c := range cs {
+			counts[c.State]++
+		}
+	}
+
+	fmt.Printf("Activity between %v and %v:\n", fromTime.Format(timeFormat), toTime.Format(timeFormat))
+	fmt.Printf("\tMerged:       %d\n", counts[StateMerged])
+	fmt.Printf("\tAbandoned:    %d\n", counts[StateAbandoned])
+	fmt.Printf("\tUnder Review: %d\n", counts[StateUnderReview])
+	fmt.Printf("\tIn Progress:  %d\n", counts[StateInProgress])
+	fmt.Printf("\tReviewed:     %d\n", counts[StateReviewed])
+	fmt.Printf("\tIssues:       %d\n", counts[StateIssue])
+}
+
+// runPerRepo fetches the same activity as runWeekly, but groups the
+// report by owner/repo instead of by status.
+func runPerRepo(startTime, endTime time.Time) {
+	es := fetchEventSets(startTime, endTime)
+
+	byRepo := make(map[string][]string)
+	for _, s := range es.sections() {
+		for _, it := range es.items(s) {
+			byRepo[it.Repo] = append(byRepo[it.Repo], fmt.Sprintf("[%s](%s) (%s)", it.Title, it.URL, s.title))
+		}
+	}
+
+	repos := make([]string, 0, len(byRepo))
+	for r := range byRepo {
+		repos = append(repos, r)
+	}
+	sort.Strings(repos)
+
+	md := []string{"* Activity by repo"}
+	for _, r := range repos {
+		md = append(md, fmt.Sprintf("\t* %s", r))
+		for _, item := range byRepo[r] {
+			md = append(md, fmt.Sprintf("\t\t* %s", item))
+		}
+	}
+	fmt.Println(strings.Join(md, "\n"))
+}
+
+// runReviewLatency computes, for each GitHub PR the user authored in the
+// window, the time from open to first review and from open to merge, then
+// prints p50/p90/mean over each.
+func runReviewLatency(startTime, endTime time.Time) {
+	gh := newGitHubForge()
+	events, err := gh.listEvents(*user)
+	if err != nil {
+		log.Fatalf("Unable to list events: %v", err)
+	}
+	ge := organizeEvents(filterEventsForTime(events, startTime, endTime))
+
+	var toFirstReview, toMerge []time.Duration
+	for url, info := range ge.prs {
+		pr, _, err := gh.client.PullRequests.Get(context.TODO(), info.owner, info.repo, info.number)
+		if err != nil {
+			log.Fatalf("Unable to get PR %q: %v", url, err)
+		}
+		if pr.GetUser().GetLogin() != *user {
+			continue
+		}
+		reviews, _, err := gh.client.PullRequests.ListReviews(context.TODO(), info.owner, info.repo, info.number, nil)
+		if err != nil {
+			log.Fatalf("Unable to list reviews for %q: %v", url, err)
+		}
+		opened := pr.GetCreatedAt()
+		if first := firstReviewTime(reviews); !first.IsZero() {
+			toFirstReview = append(toFirstReview, first.Sub(opened))
+		}
+		if pr.GetMerged() {
+			toMerge = append(toMerge, pr.GetMergedAt().Sub(opened))
+		}
+	}
+
+	fmt.Println("Time to first review:")
+	printLatencyStats(toFirstReview)
+	fmt.Println("Time to merge:")
+	printLatencyStats(toMerge)
+}
+
+func firstReviewTime(reviews []*github.PullRequestReview) time.Time {
+	var first time.Time
+	for _, r := range reviews {
+		t := r.GetSubmittedAt()
+		if first.IsZero() || t.Before(first) {
+			first = t
+		}
+	}
+	return first
+}
+
+func printLatencyStats(durations []time.Duration) {
+	if len(durations) == 0 {
+		fmt.Println("\tNo data")
+		return
+	}
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+	var sum time.Duration
+	for _, d := range durations {
+		sum += d
+	}
+	fmt.Printf("\tp50:  %v\n", percentile(durations, 0.5))
+	fmt.Printf("\tp90:  %v\n", percentile(durations, 0.9))
+	fmt.Printf("\tmean: %v\n", sum/time.Duration(len(durations)))
+}
+
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	idx := int(p * float64(len(sorted)-1))
+	return sorted[idx]
+}
+
+// runReviewers tallies, for each GitHub PR the user reviewed in the
+// window, which other users also reviewed that PR.
+func runReviewers(startTime, endTime time.Time) {
+	gh := newGitHubForge()
+	events, err := gh.listEvents(*user)
+	if err != nil {
+		log.Fatalf("Unable to list events: %v", err)
+	}
+	ge := organizeEvents(filterEventsForTime(events, startTime, endTime))
+
+	tally := make(map[string]int)
+	for url, info := range ge.prs {
+		pr, _, err := gh.client.PullRequests.Get(context.TODO(), info.owner, info.repo, info.number)
+		if err != nil {
+			log.Fatalf("Unable to get PR %q: %v", url, err)
+		}
+		if pr.GetUser().GetLogin() == *user {
+			continue
+		}
+		reviews, _, err := gh.client.PullRequests.ListReviews(context.TODO(), info.owner, info.repo, info.number, nil)
+		if err != nil {
+			log.Fatalf("Unable to list reviews for %q: %v", url, err)
+		}
+		seen := make(map[string]bool)
+		for _, r := range reviews {
+			login := r.GetUser().GetLogin()
+			if login == "" || login == *user || seen[login] {
+				continue
+			}
+			seen[login] = true
+			tally[login]++
+		}
+	}
+
+	type reviewerCount struct {
+		login string
+		count int
+	}
+	counts := make([]reviewerCount, 0, len(tally))
+	for login, n := range tally {
+		counts = append(counts, reviewerCount{login, n})
+	}
+	sort.Slice(counts, func(i, j int) bool { return counts[i].count > counts[j].count })
+
+	for _, c := range counts {
+		fmt.Printf("%s: %d\n", c.login, c.count)
+	}
+}
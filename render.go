@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"log"
+	"strings"
+	"time"
+)
+
+// Renderer turns a resolved eventSets into a report in some output format.
+type Renderer interface {
+	Render(e *eventSets) string
+}
+
+// rendererFor returns the Renderer named by --format.
+func rendererFor(format string) Renderer {
+	switch format {
+	case "", "md", "markdown":
+		return MarkdownRenderer{}
+	case "html":
+		return HTMLRenderer{}
+	case "json":
+		return JSONRenderer{}
+	case "org":
+		return OrgRenderer{}
+	default:
+		log.Fatalf("Unknown format: %q", format)
+		return nil
+	}
+}
+
+// reportItem is a single resolved contribution within a section.
+type reportItem struct {
+	URL         string
+	Title       string
+	Repo        string
+	Number      int
+	SubmittedAt time.Time
+	Tags        []string
+}
+
+// MarkdownRenderer produces the original nested-bullet weekly-snippets
+// format.
+type MarkdownRenderer struct{}
+
+func (MarkdownRenderer) Render(e *eventSets) string {
+	md := []string{"* Activity"}
+	for _, s := range e.sections() {
+		its := e.items(s)
+		if len(its) == 0 {
+			continue
+		}
+		md = append(md, fmt.Sprintf("\t* %s", s.title))
+		for _, it := range its {
+			md = append(md, fmt.Sprintf("\t\t* [%s](%s)", it.Title, it.URL))
+		}
+	}
+	markdown := strings.Join(md, "\n")
+	return strings.Replace(markdown, "\t", "    ", -1)
+}
+
+// HTMLRenderer produces a standalone page with one <section> per status
+// that has any items; empty statuses are left out.
+type HTMLRenderer struct{}
+
+var htmlReportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Activity report</title></head>
+<body>
+{{range .}}{{if .Items}}<section>
+<h2>{{.Title}}</h2>
+<ul>
+{{range .Items}}<li><a href="{{.URL}}">{{.Title}}</a></li>
+{{end}}</ul>
+</section>
+{{end}}{{end}}</body>
+</html>
+`))
+
+type htmlSection struct {
+	Title string
+	Items []reportItem
+}
+
+func (HTMLRenderer) Render(e *eventSets) string {
+	sections := make([]htmlSection, 0, len(e.sections()))
+	for _, s := range e.sections() {
+		sections = append(sections, htmlSection{Title: s.title, Items: e.items(s)})
+	}
+	var buf bytes.Buffer
+	if err := htmlReportTemplate.Execute(&buf, sections); err != nil {
+		log.Fatalf("Unable to render HTML report: %v", err)
+	}
+	return buf.String()
+}
+
+// JSONRenderer produces a stable schema keyed by status, for piping into
+// other tools or diffing week-over-week.
+type JSONRenderer struct{}
+
+type jsonItem struct {
+	URL         string   `json:"url"`
+	Title       string   `json:"title"`
+	Repo        string   `json:"repo"`
+	Number      int      `json:"number"`
+	SubmittedAt string   `json:"submitted_at,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+}
+
+func (JSONRenderer) Render(e *eventSets) string {
+	report := make(map[string][]jsonItem, len(e.sections()))
+	for _, s := range e.sections() {
+		its := e.items(s)
+		jsonItems := make([]jsonItem, 0, len(its))
+		for _, it := range its {
+			item := jsonItem{URL: it.URL, Title: it.Title, Repo: it.Repo, Number: it.Number, Tags: it.Tags}
+			if !it.SubmittedAt.IsZero() {
+				item.SubmittedAt = it.SubmittedAt.Format(time.RFC3339)
+			}
+			jsonItems = append(jsonItems, item)
+		}
+		report[jsonSectionKey(s.title)] = jsonItems
+	}
+	b, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		log.Fatalf("Unable to render JSON report: %v", err)
+	}
+	return string(b)
+}
+
+func jsonSectionKey(title string) string {
+	return strings.ToLower(strings.Replace(title, " ", "_", -1))
+}
+
+// OrgRenderer produces Emacs org-mode headings.
+type OrgRenderer struct{}
+
+func (OrgRenderer) Render(e *eventSets) string {
+	org := make([]string, 0)
+	for _, s := range e.sections() {
+		its := e.items(s)
+		if len(its) == 0 {
+			continue
+		}
+		org = append(org, fmt.Sprintf("** %s", s.title))
+		for _, it := range its {
+			org = append(org, fmt.Sprintf("*** [[%s][%s]]", it.URL, it.Title))
+		}
+	}
+	return strings.Join(org, "\n")
+}